@@ -0,0 +1,113 @@
+package slowdown
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bandwidth throttles the response body to bytesPerSecond, by pacing out the
+// writes performed by the wrapped handler. This is useful to reproduce
+// slow-network or mobile conditions, in addition to the fixed pre/post pauses
+// added by Fixed.
+//
+// Like the other Options, it can also be driven per-request through Header,
+// e.g. "delay-bandwidth: 32kb" for 32*1024 bytes/sec.
+func Bandwidth(bytesPerSecond int) Option {
+	return func(cfg *config) {
+		cfg.bandwidth = bytesPerSecond
+	}
+}
+
+// readHeaderBandwidth parses a header value such as "32kb", "4mb" or a plain
+// number of bytes/sec, e.g. "4096".
+func readHeaderBandwidth(r *http.Request, name string) (int, bool) {
+	value := r.Header.Get(name)
+	if value == "" {
+		return 0, false
+	}
+	return parseBandwidth(value)
+}
+
+func parseBandwidth(value string) (int, bool) {
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(value, "kb"):
+		multiplier = 1024
+		value = strings.TrimSuffix(value, "kb")
+	case strings.HasSuffix(value, "mb"):
+		multiplier = 1024 * 1024
+		value = strings.TrimSuffix(value, "mb")
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n * multiplier, true
+}
+
+// throttlePeriod is how often throttledWriter releases a chunk of bytes. A
+// shorter period delivers smoother pacing at the cost of more, smaller writes.
+const throttlePeriod = 100 * time.Millisecond
+
+// throttledWriter paces Write calls so that the cumulative throughput does not
+// exceed bytesPerSecond: each call is split into chunks sized for
+// throttlePeriod, written and flushed one at a time, then paced against the
+// cumulative bytes written so far (Context-aware). This way the client
+// actually observes progressive delivery, instead of the full write being
+// buffered and released at once.
+type throttledWriter struct {
+	http.ResponseWriter
+	ctx            context.Context
+	bytesPerSecond int
+	chunkSize      int
+	windowStart    time.Time
+	written        int
+}
+
+func newThrottledWriter(w http.ResponseWriter, ctx context.Context, bytesPerSecond int) *throttledWriter {
+	chunkSize := int(float64(bytesPerSecond) * throttlePeriod.Seconds())
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	return &throttledWriter{
+		ResponseWriter: w,
+		ctx:            ctx,
+		bytesPerSecond: bytesPerSecond,
+		chunkSize:      chunkSize,
+		windowStart:    time.Now(),
+	}
+}
+
+func (tw *throttledWriter) Write(p []byte) (int, error) {
+	var total int
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > tw.chunkSize {
+			chunk = chunk[:tw.chunkSize]
+		}
+
+		n, err := tw.ResponseWriter.Write(chunk)
+		tw.written += n
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+			f.Flush()
+		}
+		p = p[n:]
+
+		expected := time.Duration(float64(tw.written) / float64(tw.bytesPerSecond) * float64(time.Second))
+		if wait := expected - time.Since(tw.windowStart); wait > 0 {
+			select {
+			case <-tw.ctx.Done():
+				return total, tw.ctx.Err()
+			case <-time.After(wait):
+			}
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,94 @@
+package slowdown
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// h = Delay(h, Bandwidth(bytesPerSecond))
+func TestBandwidthThrottlesWrites(t *testing.T) {
+	const bytesPerSecond = 100
+	payload := strings.Repeat("x", 300)
+	var slowHandler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(payload))
+	}
+
+	delayedHandler := Delay(slowHandler, Fixed(0, 0), Bandwidth(bytesPerSecond))
+
+	s := httptest.NewServer(delayedHandler)
+	defer s.Close()
+
+	start := time.Now()
+	res, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	// The client should see a first, partial chunk well before the full body
+	// has been streamed: this proves the writes are paced progressively,
+	// rather than buffered and released all at once.
+	firstChunk := make([]byte, len(payload))
+	n, _ := res.Body.Read(firstChunk)
+	firstChunkElapsed := time.Since(start)
+	if n == 0 || n >= len(payload) {
+		t.Fatalf("Expected a partial first chunk, got %d of %d bytes", n, len(payload))
+	}
+	if firstChunkElapsed > 2*time.Second {
+		t.Errorf("First chunk arrived too late: %v", firstChunkElapsed)
+	}
+
+	rest, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseTime := time.Since(start)
+
+	testOuput(t, append(firstChunk[:n], rest...), payload)
+	// 300 bytes at 100 bytes/sec should take roughly 3s to fully stream.
+	testDurationWithTolerance(t, responseTime, 3*time.Second)
+}
+
+// h = Delay(h, Header(prefix))
+func TestHeaderBandwidth(t *testing.T) {
+	const prefix = "delay"
+	payload := strings.Repeat("x", 64*1024)
+	var bigHandler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(payload))
+	}
+
+	delayedHandler := Delay(bigHandler, Header(prefix))
+
+	headers := http.Header{
+		"delay-bandwidth": []string{"32kb"},
+	}
+	messageBytes, _ := call(t, delayedHandler, headers)
+
+	testOuput(t, messageBytes, payload)
+}
+
+func TestParseBandwidth(t *testing.T) {
+	cases := map[string]int{
+		"4096": 4096,
+		"32kb": 32 * 1024,
+		"2mb":  2 * 1024 * 1024,
+		"0":    0,
+		"nope": 0,
+	}
+	for input, expected := range cases {
+		bw, ok := parseBandwidth(input)
+		if expected == 0 {
+			if ok {
+				t.Errorf("parseBandwidth(%q): expected failure, got %d", input, bw)
+			}
+			continue
+		}
+		if !ok || bw != expected {
+			t.Errorf("parseBandwidth(%q) = %d, %v; want %d, true", input, bw, ok, expected)
+		}
+	}
+}
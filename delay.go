@@ -10,7 +10,8 @@ import (
 // adds 1s of latency before the execution of the wrapped handler.
 //
 // Sample usage:
-//     myHandlerFunc = slowdown.Delay(myHandlerFunc)
+//
+//	myHandlerFunc = slowdown.Delay(myHandlerFunc)
 //
 // The call to Delay may be chained with other middleware when building a handler
 // func. By default the added latency may not exceed 40s per request (20s before
@@ -27,19 +28,44 @@ func Delay(h http.HandlerFunc, opts ...Option) http.HandlerFunc {
 	}
 
 	return func(w http.ResponseWriter, r *http.Request) {
-		cfg.before(w, r)
+		effective := cfg.forRequest(r)
+		if effective == nil {
+			// Rules are configured, but none of them matched this request.
+			h(w, r)
+			return
+		}
+
+		effective.before(w, r)
 
 		if isDone(r.Context()) {
 			return
 		}
 
+		if status, ok := effective.faultFor(r); ok {
+			w.WriteHeader(status)
+			return
+		}
+
+		if effective.dropFor(r) {
+			hijackAndClose(w)
+			return
+		}
+
+		if afterBytes, ok := effective.truncateFor(r); ok {
+			w = newTruncatingWriter(w, afterBytes)
+		}
+
+		if bw := effective.bandwidthFor(r); bw > 0 {
+			w = newThrottledWriter(w, r.Context(), bw)
+		}
+
 		h(w, r)
 
 		if isDone(r.Context()) {
 			return
 		}
 
-		cfg.after(w, r)
+		effective.after(w, r)
 	}
 }
 
@@ -51,6 +77,34 @@ type config struct {
 	headerPrefix        string
 	max                 time.Duration
 	conditions          []func(*http.Request) bool
+	bandwidth           int
+	jitter              Distribution
+	faultProbability    float64
+	faultStatus         int
+	dropProbability     float64
+	truncateProbability float64
+	truncateAfterBytes  int
+	rules               []Rule
+}
+
+// forRequest returns the config to apply to r: cfg itself when Rules is not
+// used, the config built from the first matching Rule's Options when it is,
+// or nil when Rules is used but none of them match (meaning: pass through
+// undelayed).
+func (cfg *config) forRequest(r *http.Request) *config {
+	if len(cfg.rules) == 0 {
+		return cfg
+	}
+	for _, rule := range cfg.rules {
+		if rule.matches(r) {
+			ruleCfg := &config{max: 20 * time.Second}
+			for _, opt := range rule.Options {
+				opt(ruleCfg)
+			}
+			return ruleCfg
+		}
+	}
+	return nil
 }
 
 // Option configures the behavior of the delayed handler.
@@ -65,14 +119,37 @@ func (cfg *config) after(w http.ResponseWriter, r *http.Request) {
 }
 
 func (cfg *config) sleep(w http.ResponseWriter, r *http.Request, beforeOrAfter string) {
+	cfg.sleepCtx(r, beforeOrAfter)
+}
+
+// sleepCtx pauses for the configured before/after duration, the same way sleep
+// does, but is also usable without a ResponseWriter (e.g. from Transport) and
+// reports back whether the wait was interrupted by the Request's Context.
+func (cfg *config) sleepCtx(r *http.Request, beforeOrAfter string) error {
+	d := cfg.duration(r, beforeOrAfter)
+	// Like time.Sleep(d), but Context-aware
+	select {
+	case <-r.Context().Done(): //context cancelled
+		return r.Context().Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// duration computes how long to pause before or after, honoring Header, Fixed
+// and Max, for the given request.
+func (cfg *config) duration(r *http.Request, beforeOrAfter string) time.Duration {
 	if !cfg.checkConditions(r) {
 		// When at least one condition is not met, there is no delay added.
-		return
+		return 0
 	}
 
 	var d time.Duration
 	if cfg.headerPrefix != "" {
 		d, _ = readHeaderDuration(r, cfg.headerPrefix+"-"+beforeOrAfter)
+		if dist, ok := readHeaderDistribution(r, cfg.headerPrefix+"-jitter-"+beforeOrAfter); ok {
+			d += dist.Sample()
+		}
 	} else {
 		switch beforeOrAfter {
 		case "before":
@@ -80,15 +157,17 @@ func (cfg *config) sleep(w http.ResponseWriter, r *http.Request, beforeOrAfter s
 		case "after":
 			d = cfg.fixedDurationAfter
 		}
+		if cfg.jitter != nil {
+			d += cfg.jitter.Sample()
+		}
+	}
+	if d < 0 {
+		d = 0
 	}
 	if d > cfg.max {
 		d = cfg.max
 	}
-	// Like time.Sleep(d), but Context-aware
-	select {
-	case <-r.Context().Done(): //context cancelled
-	case <-time.After(d):
-	}
+	return d
 }
 
 // Fixed sets how long to pause before and after the wrapped HandlerFunc is executed.
@@ -160,6 +239,20 @@ func (cfg *config) checkConditions(r *http.Request) bool {
 	return true
 }
 
+// bandwidthFor returns the bytes/sec throttling rate to apply for r, or 0 for
+// no throttling. It honors Header the same way duration does: when Header is
+// set, only a matching per-request header can trigger throttling.
+func (cfg *config) bandwidthFor(r *http.Request) int {
+	if !cfg.checkConditions(r) {
+		return 0
+	}
+	if cfg.headerPrefix != "" {
+		bw, _ := readHeaderBandwidth(r, cfg.headerPrefix+"-bandwidth")
+		return bw
+	}
+	return cfg.bandwidth
+}
+
 // Helper to determine if a Context is already done (cancelled), in an imperative style.
 func isDone(ctx context.Context) bool {
 	select {
@@ -2,7 +2,9 @@
 // to an http.HandlerFunc.
 //
 // Sample usage:
-//     h = slowdown.Delay(h, slowdown.Header("delay"), slowdown.Max(5*time.Second))
+//
+//	h = slowdown.Delay(h, slowdown.Header("delay"), slowdown.Max(5*time.Second))
+//
 // which means "Accept request headers 'delay-before' and 'delay-after'
 // and pause the request processing accordingly, but never more than 5s."
 //
@@ -0,0 +1,161 @@
+package slowdown
+
+import (
+	"errors"
+	"math/rand"
+	"net/http"
+	"strconv"
+)
+
+// errTruncated is returned by truncatingWriter.Write once the response has
+// already been cut short and the connection closed.
+var errTruncated = errors.New("slowdown: response truncated")
+
+// Fault makes the wrapped handler return status instead of being invoked, with
+// the given probability (between 0 and 1). This simulates a dependency
+// returning errors under load.
+//
+// Like the other Options, it can also be driven per-request through Header,
+// e.g. "delay-fault-status: 503" always triggers the fault for that request.
+func Fault(probability float64, status int) Option {
+	return func(cfg *config) {
+		cfg.faultProbability = probability
+		cfg.faultStatus = status
+	}
+}
+
+// Drop hijacks and closes the connection, with the given probability, instead
+// of writing any response. This simulates a peer abruptly resetting the
+// connection.
+//
+// Like the other Options, it can also be driven per-request through Header,
+// e.g. "delay-drop: 1" always triggers the drop for that request.
+func Drop(probability float64) Option {
+	return func(cfg *config) {
+		cfg.dropProbability = probability
+	}
+}
+
+// Truncate wraps the response in a writer that stops forwarding bytes after
+// afterBytes have been written, then hijacks and closes the connection. This
+// simulates a peer cutting a response short mid-stream, with the given
+// probability.
+//
+// Like the other Options, it can also be driven per-request through Header,
+// e.g. "delay-truncate: 128" always truncates that request's response after
+// 128 bytes.
+func Truncate(probability float64, afterBytes int) Option {
+	return func(cfg *config) {
+		cfg.truncateProbability = probability
+		cfg.truncateAfterBytes = afterBytes
+	}
+}
+
+// faultFor returns the status to return for r, and whether a fault was
+// triggered at all.
+func (cfg *config) faultFor(r *http.Request) (int, bool) {
+	if !cfg.checkConditions(r) {
+		return 0, false
+	}
+	if cfg.headerPrefix != "" {
+		status, err := strconv.Atoi(r.Header.Get(cfg.headerPrefix + "-fault-status"))
+		if err != nil {
+			return 0, false
+		}
+		return status, true
+	}
+	if cfg.faultProbability > 0 && rand.Float64() < cfg.faultProbability {
+		return cfg.faultStatus, true
+	}
+	return 0, false
+}
+
+// dropFor reports whether the connection for r should be dropped.
+func (cfg *config) dropFor(r *http.Request) bool {
+	if !cfg.checkConditions(r) {
+		return false
+	}
+	if cfg.headerPrefix != "" {
+		return r.Header.Get(cfg.headerPrefix+"-drop") == "1"
+	}
+	return cfg.dropProbability > 0 && rand.Float64() < cfg.dropProbability
+}
+
+// truncateFor returns the number of bytes to allow through for r before
+// truncating, and whether truncation was triggered at all.
+func (cfg *config) truncateFor(r *http.Request) (int, bool) {
+	if !cfg.checkConditions(r) {
+		return 0, false
+	}
+	if cfg.headerPrefix != "" {
+		afterBytes, err := strconv.Atoi(r.Header.Get(cfg.headerPrefix + "-truncate"))
+		if err != nil {
+			return 0, false
+		}
+		return afterBytes, true
+	}
+	if cfg.truncateProbability > 0 && rand.Float64() < cfg.truncateProbability {
+		return cfg.truncateAfterBytes, true
+	}
+	return 0, false
+}
+
+// hijackAndClose hijacks w's underlying connection and closes it immediately,
+// if w supports hijacking. Otherwise it does nothing.
+func hijackAndClose(w http.ResponseWriter) {
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		return
+	}
+	conn, _, err := hj.Hijack()
+	if err != nil {
+		return
+	}
+	conn.Close()
+}
+
+// truncatingWriter stops forwarding bytes once remaining reaches 0, then
+// hijacks and closes the underlying connection.
+type truncatingWriter struct {
+	http.ResponseWriter
+	remaining int
+	closed    bool
+}
+
+func newTruncatingWriter(w http.ResponseWriter, afterBytes int) *truncatingWriter {
+	return &truncatingWriter{ResponseWriter: w, remaining: afterBytes}
+}
+
+func (tw *truncatingWriter) Write(p []byte) (int, error) {
+	if tw.closed {
+		return 0, errTruncated
+	}
+
+	truncated := len(p) > tw.remaining
+	if truncated {
+		p = p[:tw.remaining]
+	}
+
+	n, err := tw.ResponseWriter.Write(p)
+	tw.remaining -= n
+	if err != nil {
+		return n, err
+	}
+
+	if tw.remaining <= 0 {
+		tw.closed = true
+		// Flush first, so that the bytes allowed through reach the client
+		// before the connection is closed.
+		if f, ok := tw.ResponseWriter.(http.Flusher); ok {
+			f.Flush()
+		}
+		hijackAndClose(tw.ResponseWriter)
+	}
+	if truncated {
+		// Per the io.Writer contract, a short write must be reported with a
+		// non-nil error on the very call that falls short, not just on the
+		// next one.
+		return n, errTruncated
+	}
+	return n, nil
+}
@@ -0,0 +1,139 @@
+package slowdown
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// h = Delay(h, Fixed(0, 0), Fault(1, status))
+func TestFaultAlwaysTriggered(t *testing.T) {
+	var sideEffectHandler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Should not have hit the wrapped handler")
+	}
+	delayedHandler := Delay(sideEffectHandler, Fixed(0, 0), Fault(1, http.StatusServiceUnavailable))
+
+	s := httptest.NewServer(delayedHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, res.StatusCode)
+	}
+}
+
+// h = Delay(h, Fixed(0, 0), Fault(0, status))
+func TestFaultNeverTriggered(t *testing.T) {
+	delayedHandler := Delay(helloWorld, Fixed(0, 0), Fault(0, http.StatusServiceUnavailable))
+
+	messageBytes, _ := call(t, delayedHandler, nil)
+
+	testOuput(t, messageBytes, "Hello world\n")
+}
+
+// h = Delay(h, Header(prefix))
+func TestHeaderFault(t *testing.T) {
+	const prefix = "delay"
+	var sideEffectHandler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Should not have hit the wrapped handler")
+	}
+	delayedHandler := Delay(sideEffectHandler, Header(prefix))
+
+	s := httptest.NewServer(delayedHandler)
+	defer s.Close()
+
+	req, _ := http.NewRequest("GET", s.URL, nil)
+	req.Header.Set("delay-fault-status", "503")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected status %d, got %d", http.StatusServiceUnavailable, res.StatusCode)
+	}
+}
+
+// h = Delay(h, Fixed(0, 0), Drop(1))
+func TestDropAlwaysTriggered(t *testing.T) {
+	var sideEffectHandler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Should not have hit the wrapped handler")
+	}
+	delayedHandler := Delay(sideEffectHandler, Fixed(0, 0), Drop(1))
+
+	s := httptest.NewServer(delayedHandler)
+	defer s.Close()
+
+	_, err := http.Get(s.URL)
+	if err == nil {
+		t.Fatal("Expected an error from a dropped connection, got nil")
+	}
+}
+
+// h = Delay(h, Fixed(0, 0), Truncate(1, afterBytes))
+func TestTruncateAlwaysTriggered(t *testing.T) {
+	const afterBytes = 5
+	payload := strings.Repeat("x", 100)
+	var bigHandler http.HandlerFunc = func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(payload))
+	}
+	delayedHandler := Delay(bigHandler, Fixed(0, 0), Truncate(1, afterBytes))
+
+	s := httptest.NewServer(delayedHandler)
+	defer s.Close()
+
+	res, err := http.Get(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body := make([]byte, len(payload))
+	n, _ := res.Body.Read(body)
+	if n > afterBytes {
+		t.Errorf("Expected at most %d bytes, got %d", afterBytes, n)
+	}
+}
+
+// h = Delay(h, Fixed(0, 0), Fault(1, status), Drop(1), Truncate(1, afterBytes), Condition(never))
+func TestConditionGatesFaultDropTruncate(t *testing.T) {
+	const afterBytes = 5
+	never := func(r *http.Request) bool { return false }
+
+	delayedHandler := Delay(helloWorld, Fixed(0, 0),
+		Fault(1, http.StatusServiceUnavailable),
+		Drop(1),
+		Truncate(1, afterBytes),
+		Condition(never),
+	)
+
+	messageBytes, _ := call(t, delayedHandler, nil)
+
+	testOuput(t, messageBytes, "Hello world\n")
+}
+
+// io.Writer requires a non-nil error whenever n < len(p) (see io.Writer).
+func TestTruncatingWriterShortWriteReturnsError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	tw := newTruncatingWriter(rec, 5)
+
+	n, err := tw.Write([]byte("0123456789"))
+	if n != 5 {
+		t.Errorf("Expected 5 bytes written, got %d", n)
+	}
+	if err == nil {
+		t.Error("Expected a non-nil error from the write that triggers truncation, got nil")
+	}
+
+	n2, err2 := tw.Write([]byte("more"))
+	if n2 != 0 || err2 == nil {
+		t.Errorf("Expected subsequent writes to fail, got n=%d err=%v", n2, err2)
+	}
+}
@@ -0,0 +1,117 @@
+package slowdown
+
+import (
+	"math"
+	"math/rand"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Distribution samples random durations, used by Jitter to add randomness to
+// the before/after delays.
+type Distribution interface {
+	Sample() time.Duration
+}
+
+// Jitter adds, on top of the configured before/after delays, a random extra
+// duration sampled from dist. The resulting total (base + jitter) is still
+// capped at 0 and by Max.
+//
+// Like the other Options, it can also be driven per-request through Header,
+// e.g. "delay-jitter-before: normal:200ms:50ms".
+func Jitter(dist Distribution) Option {
+	return func(cfg *config) {
+		cfg.jitter = dist
+	}
+}
+
+type uniformDistribution struct {
+	min, max time.Duration
+}
+
+// Uniform returns a Distribution sampling uniformly between min and max.
+func Uniform(min, max time.Duration) Distribution {
+	return uniformDistribution{min: min, max: max}
+}
+
+func (u uniformDistribution) Sample() time.Duration {
+	return u.min + time.Duration(rand.Float64()*float64(u.max-u.min))
+}
+
+type normalDistribution struct {
+	mean, stddev time.Duration
+}
+
+// Normal returns a Distribution sampling from a normal (Gaussian) distribution
+// with the given mean and standard deviation, using the Box-Muller transform.
+func Normal(mean, stddev time.Duration) Distribution {
+	return normalDistribution{mean: mean, stddev: stddev}
+}
+
+func (n normalDistribution) Sample() time.Duration {
+	u1, u2 := rand.Float64(), rand.Float64()
+	z := math.Sqrt(-2*math.Log(u1)) * math.Cos(2*math.Pi*u2)
+	return time.Duration(float64(n.mean) + z*float64(n.stddev))
+}
+
+type exponentialDistribution struct {
+	mean time.Duration
+}
+
+// Exponential returns a Distribution sampling from an exponential
+// distribution with the given mean, useful for modeling tail latencies.
+func Exponential(mean time.Duration) Distribution {
+	return exponentialDistribution{mean: mean}
+}
+
+func (e exponentialDistribution) Sample() time.Duration {
+	return time.Duration(-float64(e.mean) * math.Log(1-rand.Float64()))
+}
+
+// parseDistribution parses the header form of a Distribution, e.g.
+// "uniform:100ms:300ms", "normal:200ms:50ms" or "exponential:150ms".
+func parseDistribution(value string) (Distribution, bool) {
+	parts := strings.Split(value, ":")
+	switch parts[0] {
+	case "uniform":
+		if len(parts) != 3 {
+			return nil, false
+		}
+		min, err1 := time.ParseDuration(parts[1])
+		max, err2 := time.ParseDuration(parts[2])
+		if err1 != nil || err2 != nil {
+			return nil, false
+		}
+		return Uniform(min, max), true
+	case "normal":
+		if len(parts) != 3 {
+			return nil, false
+		}
+		mean, err1 := time.ParseDuration(parts[1])
+		stddev, err2 := time.ParseDuration(parts[2])
+		if err1 != nil || err2 != nil {
+			return nil, false
+		}
+		return Normal(mean, stddev), true
+	case "exponential":
+		if len(parts) != 2 {
+			return nil, false
+		}
+		mean, err := time.ParseDuration(parts[1])
+		if err != nil {
+			return nil, false
+		}
+		return Exponential(mean), true
+	default:
+		return nil, false
+	}
+}
+
+func readHeaderDistribution(r *http.Request, name string) (Distribution, bool) {
+	value := r.Header.Get(name)
+	if value == "" {
+		return nil, false
+	}
+	return parseDistribution(value)
+}
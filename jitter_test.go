@@ -0,0 +1,68 @@
+package slowdown
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+// h = Delay(h, Fixed(d, 0), Jitter(Uniform(min, max)))
+func TestJitterUniformAddsWithinBounds(t *testing.T) {
+	const fixed = 200 * time.Millisecond
+	const jitterMin = 50 * time.Millisecond
+	const jitterMax = 150 * time.Millisecond
+
+	delayedHandler := Delay(helloWorld, Fixed(fixed, 0), Jitter(Uniform(jitterMin, jitterMax)), Max(1*time.Second))
+
+	_, responseTime := call(t, delayedHandler, nil)
+
+	if responseTime < fixed+jitterMin-50*time.Millisecond {
+		t.Errorf("Response time too short: %v", responseTime)
+	}
+	if responseTime > fixed+jitterMax+100*time.Millisecond {
+		t.Errorf("Response time too long: %v", responseTime)
+	}
+}
+
+// h = Delay(h, Header(prefix))
+func TestHeaderJitterNormal(t *testing.T) {
+	const prefix = "delay"
+	const base = 100 * time.Millisecond
+	// The "normal:50ms:10ms" jitter is added on top of base. Its mean is
+	// 50ms with a 10ms stddev, so the sampled value lands above 20ms (3
+	// stddevs below the mean) well over 99.8% of the time. Asserting this
+	// lower bound, comfortably above base alone, catches a regression where
+	// the header-driven jitter is silently ignored, without making the test
+	// flaky.
+	const minExpected = base + 20*time.Millisecond
+
+	delayedHandler := Delay(helloWorld, Header(prefix), Max(2*time.Second))
+
+	headers := http.Header{
+		"delay-before":        []string{"100ms"},
+		"delay-jitter-before": []string{"normal:50ms:10ms"},
+	}
+	_, responseTime := call(t, delayedHandler, headers)
+
+	if responseTime < minExpected {
+		t.Errorf("Response time too short, expected jitter on top of %v base: got %v", base, responseTime)
+	}
+	if responseTime > 2*time.Second {
+		t.Errorf("Response time should have been capped by Max, got %v", responseTime)
+	}
+}
+
+func TestParseDistribution(t *testing.T) {
+	if _, ok := parseDistribution("uniform:100ms:300ms"); !ok {
+		t.Error("expected uniform distribution to parse")
+	}
+	if _, ok := parseDistribution("normal:200ms:50ms"); !ok {
+		t.Error("expected normal distribution to parse")
+	}
+	if _, ok := parseDistribution("exponential:150ms"); !ok {
+		t.Error("expected exponential distribution to parse")
+	}
+	if _, ok := parseDistribution("bogus:1:2"); ok {
+		t.Error("expected bogus distribution to fail parsing")
+	}
+}
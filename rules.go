@@ -0,0 +1,141 @@
+package slowdown
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"time"
+)
+
+// Rule associates a request matcher (Method and PathPattern) with the Options
+// to apply only to matching requests.
+//
+// PathPattern is matched against the request's URL path with path.Match, so
+// "/api/*" matches "/api/users" but not "/api/users/42". An empty Method or
+// PathPattern matches any method or path, respectively.
+type Rule struct {
+	Method      string
+	PathPattern string
+	Options     []Option
+}
+
+// Rules configures per-path/method delay rules. Incoming requests are matched
+// against rs in order, first-match-wins: the Options of the first matching
+// Rule are applied, and the rest of rs is ignored. A request matching no Rule
+// passes through with no delay at all.
+//
+// Rules should not be combined with other Options passed to Delay: when Rules
+// is set, those other Options are ignored, and only the Options of the
+// matching Rule apply to a request.
+func Rules(rs []Rule) Option {
+	return func(cfg *config) {
+		cfg.rules = rs
+	}
+}
+
+func (rule Rule) matches(r *http.Request) bool {
+	if rule.Method != "" && rule.Method != r.Method {
+		return false
+	}
+	if rule.PathPattern == "" {
+		return true
+	}
+	matched, err := path.Match(rule.PathPattern, r.URL.Path)
+	return err == nil && matched
+}
+
+// jsonRule is the document shape accepted by RulesFromJSON and RulesFromYAML.
+type jsonRule struct {
+	Path   string          `json:"path" yaml:"path"`
+	Method string          `json:"method" yaml:"method"`
+	Before string          `json:"before" yaml:"before"`
+	After  string          `json:"after" yaml:"after"`
+	Max    string          `json:"max" yaml:"max"`
+	Jitter *jsonRuleJitter `json:"jitter" yaml:"jitter"`
+}
+
+// jsonRuleJitter is the document shape of a rule's "jitter" field. Which
+// fields apply depends on "kind": "uniform" reads "min"/"max", "normal" reads
+// "mean"/"stddev", and "exponential" reads "mean".
+type jsonRuleJitter struct {
+	Kind   string `json:"kind" yaml:"kind"`
+	Min    string `json:"min" yaml:"min"`
+	Max    string `json:"max" yaml:"max"`
+	Mean   string `json:"mean" yaml:"mean"`
+	Stddev string `json:"stddev" yaml:"stddev"`
+}
+
+// RulesFromJSON reads a JSON array of rule definitions and returns the
+// equivalent Rules Option. Each entry may set "path", "method", "before",
+// "after", "max" (parsable durations, see time.ParseDuration) and "jitter"
+// (see Jitter and jsonRuleJitter for its shape, which depends on "kind"), e.g.:
+//
+//	[{ "path": "/api/*", "method": "GET", "before": "500ms", "after": "1s",
+//	   "jitter": {"kind": "normal", "mean": "100ms", "stddev": "20ms"}, "max": "5s" }]
+//
+// A uniform jitter instead reads "min"/"max":
+//
+//	{"kind": "uniform", "min": "50ms", "max": "300ms"}
+func RulesFromJSON(r io.Reader) (Option, error) {
+	var entries []jsonRule
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, err
+	}
+	return rulesFromEntries(entries)
+}
+
+func rulesFromEntries(entries []jsonRule) (Option, error) {
+	rules := make([]Rule, 0, len(entries))
+	for _, e := range entries {
+		before, err := parseOptionalDuration(e.Before)
+		if err != nil {
+			return nil, fmt.Errorf("slowdown: invalid before duration %q: %w", e.Before, err)
+		}
+		after, err := parseOptionalDuration(e.After)
+		if err != nil {
+			return nil, fmt.Errorf("slowdown: invalid after duration %q: %w", e.After, err)
+		}
+		opts := []Option{Fixed(before, after)}
+
+		if e.Max != "" {
+			max, err := time.ParseDuration(e.Max)
+			if err != nil {
+				return nil, fmt.Errorf("slowdown: invalid max duration %q: %w", e.Max, err)
+			}
+			opts = append(opts, Max(max))
+		}
+
+		if e.Jitter != nil {
+			dist, ok := jitterFromJSON(e.Jitter)
+			if !ok {
+				return nil, fmt.Errorf("slowdown: invalid jitter %+v", e.Jitter)
+			}
+			opts = append(opts, Jitter(dist))
+		}
+
+		rules = append(rules, Rule{Method: e.Method, PathPattern: e.Path, Options: opts})
+	}
+	return Rules(rules), nil
+}
+
+func parseOptionalDuration(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(value)
+}
+
+func jitterFromJSON(j *jsonRuleJitter) (Distribution, bool) {
+	switch j.Kind {
+	case "uniform":
+		return parseDistribution(j.Kind + ":" + j.Min + ":" + j.Max)
+	case "normal":
+		return parseDistribution(j.Kind + ":" + j.Mean + ":" + j.Stddev)
+	case "exponential":
+		return parseDistribution(j.Kind + ":" + j.Mean)
+	default:
+		return nil, false
+	}
+}
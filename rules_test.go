@@ -0,0 +1,166 @@
+package slowdown
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// h = Delay(h, Rules(rs))
+func TestRulesMatchingAppliesItsOptions(t *testing.T) {
+	const apiLatency = 200 * time.Millisecond
+	rules := []Rule{
+		{Method: "GET", PathPattern: "/api/*", Options: []Option{Fixed(apiLatency, 0)}},
+		{PathPattern: "/*", Options: []Option{Fixed(0, 0)}},
+	}
+	delayedHandler := Delay(helloWorld, Rules(rules))
+
+	s := httptest.NewServer(delayedHandler)
+	defer s.Close()
+
+	responseTime := clock(func() {
+		res, err := http.Get(s.URL + "/api/users")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	})
+
+	testDurationWithTolerance(t, responseTime, apiLatency)
+}
+
+// h = Delay(h, Rules(rs))
+func TestRulesNoMatchPassesThroughUndelayed(t *testing.T) {
+	rules := []Rule{
+		{Method: "GET", PathPattern: "/api/*", Options: []Option{Fixed(5*time.Second, 0)}},
+	}
+	delayedHandler := Delay(helloWorld, Rules(rules))
+
+	s := httptest.NewServer(delayedHandler)
+	defer s.Close()
+
+	responseTime := clock(func() {
+		res, err := http.Get(s.URL + "/other")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+	})
+
+	testDurationWithTolerance(t, responseTime, 0)
+}
+
+func TestRuleMatches(t *testing.T) {
+	rule := Rule{Method: "GET", PathPattern: "/api/*"}
+
+	match, _ := http.NewRequest("GET", "http://example.com/api/users", nil)
+	if !rule.matches(match) {
+		t.Error("expected rule to match GET /api/users")
+	}
+
+	wrongMethod, _ := http.NewRequest("POST", "http://example.com/api/users", nil)
+	if rule.matches(wrongMethod) {
+		t.Error("expected rule not to match POST /api/users")
+	}
+
+	wrongPath, _ := http.NewRequest("GET", "http://example.com/other", nil)
+	if rule.matches(wrongPath) {
+		t.Error("expected rule not to match GET /other")
+	}
+}
+
+func TestRulesFromJSON(t *testing.T) {
+	doc := `[
+		{"path": "/api/*", "method": "GET", "before": "500ms", "after": "1s",
+		 "jitter": {"kind": "normal", "mean": "100ms", "stddev": "20ms"}, "max": "5s"}
+	]`
+
+	opt, err := RulesFromJSON(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg config
+	opt(&cfg)
+	if len(cfg.rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(cfg.rules))
+	}
+	if cfg.rules[0].Method != "GET" || cfg.rules[0].PathPattern != "/api/*" {
+		t.Errorf("unexpected rule matcher: %+v", cfg.rules[0])
+	}
+}
+
+func TestRulesFromJSONUniformAndExponentialJitter(t *testing.T) {
+	doc := `[
+		{"path": "/a", "jitter": {"kind": "uniform", "min": "10ms", "max": "30ms"}},
+		{"path": "/b", "jitter": {"kind": "exponential", "mean": "100ms"}}
+	]`
+
+	opt, err := RulesFromJSON(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg config
+	opt(&cfg)
+	if len(cfg.rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(cfg.rules))
+	}
+}
+
+func TestRulesFromYAML(t *testing.T) {
+	doc := "" +
+		"- path: /api/*\n" +
+		"  method: GET\n" +
+		"  before: 500ms\n" +
+		"  after: 1s\n" +
+		"  max: 5s\n" +
+		"  jitter:\n" +
+		"    kind: normal\n" +
+		"    mean: 100ms\n" +
+		"    stddev: 20ms\n"
+
+	opt, err := RulesFromYAML(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var cfg config
+	opt(&cfg)
+	if len(cfg.rules) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(cfg.rules))
+	}
+	if cfg.rules[0].Method != "GET" || cfg.rules[0].PathPattern != "/api/*" {
+		t.Errorf("unexpected rule matcher: %+v", cfg.rules[0])
+	}
+}
+
+// A rule-level "max:" following a "jitter: {kind: uniform, ...}" block shares
+// its field name with the jitter's own "max" (the upper bound of the uniform
+// distribution). It must still be parsed as the rule's cap, not swallowed
+// into the jitter block.
+func TestRulesFromYAMLMaxAfterUniformJitter(t *testing.T) {
+	doc := "" +
+		"- path: /api/*\n" +
+		"  jitter:\n" +
+		"    kind: uniform\n" +
+		"    min: 10ms\n" +
+		"    max: 30ms\n" +
+		"  max: 5s\n"
+
+	entries, err := parseYAMLRules(strings.NewReader(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 rule, got %d", len(entries))
+	}
+	if entries[0].Max != "5s" {
+		t.Errorf("expected rule-level max %q, got %q", "5s", entries[0].Max)
+	}
+	if entries[0].Jitter == nil || entries[0].Jitter.Max != "30ms" {
+		t.Errorf("expected jitter max %q, got %+v", "30ms", entries[0].Jitter)
+	}
+}
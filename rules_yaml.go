@@ -0,0 +1,139 @@
+package slowdown
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// RulesFromYAML reads a YAML document of rule definitions, in the same shape
+// as RulesFromJSON, and returns the equivalent Rules Option. A document is a
+// list of one or more rules, each starting with a "key: value" item prefixed
+// by a dash, e.g. a document with a single rule reads:
+//
+//	path: /api/*
+//	method: GET
+//	before: 500ms
+//	after: 1s
+//	max: 5s
+//	jitter:
+//	  kind: normal
+//	  mean: 100ms
+//	  stddev: 20ms
+//
+// with a leading dash ("- path: /api/*") on its first line, to mark it as a
+// list item.
+//
+// Only this flat subset of YAML is understood: one Rule per top-level list
+// item, scalar "key: value" fields, and a single nested "jitter:" mapping.
+// There is no dependency on a general-purpose YAML library; this keeps the
+// package dependency-free, at the cost of not supporting the full YAML spec
+// (quoting, multi-line scalars, anchors, etc).
+func RulesFromYAML(r io.Reader) (Option, error) {
+	entries, err := parseYAMLRules(r)
+	if err != nil {
+		return nil, err
+	}
+	return rulesFromEntries(entries)
+}
+
+func parseYAMLRules(r io.Reader) ([]jsonRule, error) {
+	var entries []jsonRule
+	var current *jsonRule
+	// jitterIndent is the column at which "jitter:" was declared; a line is a
+	// jitter sub-field only while it is indented further than that,
+	// regardless of whether its key name also happens to exist at the rule
+	// level (e.g. "max").
+	inJitter := false
+	jitterIndent := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		if strings.HasPrefix(trimmed, "- ") {
+			entries = append(entries, jsonRule{})
+			current = &entries[len(entries)-1]
+			inJitter = false
+			trimmed = strings.TrimPrefix(trimmed, "- ")
+		}
+		if current == nil {
+			return nil, fmt.Errorf("slowdown: expected a top-level \"- \" list item, got %q", line)
+		}
+
+		if inJitter && indent <= jitterIndent {
+			// This line is indented back to the rule level (or shallower),
+			// so it dedents out of "jitter:" even if its key name also
+			// happens to be a valid jitter field (e.g. "max").
+			inJitter = false
+		}
+
+		if trimmed == "jitter:" {
+			current.Jitter = &jsonRuleJitter{}
+			inJitter = true
+			jitterIndent = indent
+			continue
+		}
+
+		key, value, ok := splitYAMLField(trimmed)
+		if !ok {
+			return nil, fmt.Errorf("slowdown: cannot parse YAML line %q", line)
+		}
+
+		if inJitter {
+			switch key {
+			case "kind":
+				current.Jitter.Kind = value
+			case "min":
+				current.Jitter.Min = value
+			case "max":
+				current.Jitter.Max = value
+			case "mean":
+				current.Jitter.Mean = value
+			case "stddev":
+				current.Jitter.Stddev = value
+			default:
+				return nil, fmt.Errorf("slowdown: unknown jitter field %q", key)
+			}
+			continue
+		}
+
+		switch key {
+		case "path":
+			current.Path = value
+		case "method":
+			current.Method = value
+		case "before":
+			current.Before = value
+		case "after":
+			current.After = value
+		case "max":
+			current.Max = value
+		default:
+			return nil, fmt.Errorf("slowdown: unknown rule field %q", key)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// splitYAMLField splits a "key: value" line, trimming surrounding quotes from
+// the value if present.
+func splitYAMLField(s string) (key, value string, ok bool) {
+	i := strings.Index(s, ":")
+	if i < 0 {
+		return "", "", false
+	}
+	key = strings.TrimSpace(s[:i])
+	value = strings.TrimSpace(s[i+1:])
+	value = strings.Trim(value, `"'`)
+	return key, value, true
+}
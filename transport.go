@@ -0,0 +1,64 @@
+package slowdown
+
+import (
+	"net/http"
+	"time"
+)
+
+// Transport wraps rt with the same before/after delays as Delay, but applies
+// them client-side: once before the request is sent, and once after its
+// response headers have been received. If rt is nil, http.DefaultTransport
+// is used.
+//
+// Sample usage:
+//
+//	client := &http.Client{
+//	    Transport: slowdown.Transport(nil, slowdown.Fixed(2*time.Second, 0)),
+//	}
+//
+// This lets callers reproduce a slow or flaky dependency (e.g. a degraded
+// upstream API) from the client side, without needing to control the server.
+// Like Delay, it honors the request's Context for cancellation.
+func Transport(rt http.RoundTripper, opts ...Option) http.RoundTripper {
+	// Default config values
+	cfg := config{
+		fixedDurationBefore: 1 * time.Second,
+		max:                 20 * time.Second,
+	}
+	// Apply options
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+
+	return &delayedTransport{rt: rt, cfg: cfg}
+}
+
+// delayedTransport is the http.RoundTripper returned by Transport.
+type delayedTransport struct {
+	rt  http.RoundTripper
+	cfg config
+}
+
+func (t *delayedTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if err := t.cfg.sleepCtx(r, "before"); err != nil {
+		return nil, err
+	}
+
+	res, err := t.rt.RoundTrip(r)
+	if err != nil {
+		return res, err
+	}
+
+	// The after-delay is best-effort: once a response has been obtained, a
+	// Context cancellation during this sleep must not turn into a (response,
+	// error) pair, since http.RoundTripper requires err == nil whenever a
+	// response was obtained. http.Client would otherwise discard res without
+	// closing its Body, leaking the underlying connection.
+	t.cfg.sleepCtx(r, "after")
+
+	return res, nil
+}
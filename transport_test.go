@@ -0,0 +1,131 @@
+package slowdown
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Warning: these tests are SLOW because they need to Sleep a lot.
+
+// client = &http.Client{Transport: Transport(nil, Fixed(d, 0))}
+func TestTransportFixedDelayBefore(t *testing.T) {
+	const extraLatency = 400 * time.Millisecond
+
+	s := httptest.NewServer(helloWorld)
+	defer s.Close()
+	client := &http.Client{Transport: Transport(nil, Fixed(extraLatency, 0))}
+
+	messageBytes, responseTime := callClient(t, client, s.URL)
+
+	testOuput(t, messageBytes, "Hello world\n")
+	testDurationWithTolerance(t, responseTime, extraLatency)
+}
+
+// client = &http.Client{Transport: Transport(nil, Fixed(0, d))}
+func TestTransportFixedDelayAfter(t *testing.T) {
+	const extraLatency = 400 * time.Millisecond
+
+	s := httptest.NewServer(helloWorld)
+	defer s.Close()
+	client := &http.Client{Transport: Transport(nil, Fixed(0, extraLatency))}
+
+	messageBytes, responseTime := callClient(t, client, s.URL)
+
+	testOuput(t, messageBytes, "Hello world\n")
+	testDurationWithTolerance(t, responseTime, extraLatency)
+}
+
+// client = &http.Client{Transport: Transport(underlying, Fixed(d1, d2))}
+func TestTransportWrapsUnderlyingRoundTripper(t *testing.T) {
+	const extraLatencyBefore = 200 * time.Millisecond
+	const extraLatencyAfter = 300 * time.Millisecond
+
+	s := httptest.NewServer(helloWorld)
+	defer s.Close()
+	client := &http.Client{
+		Transport: Transport(http.DefaultTransport, Fixed(extraLatencyBefore, extraLatencyAfter)),
+	}
+
+	messageBytes, responseTime := callClient(t, client, s.URL)
+
+	testOuput(t, messageBytes, "Hello world\n")
+	testDurationWithTolerance(t, responseTime, extraLatencyBefore+extraLatencyAfter)
+}
+
+// client = &http.Client{Transport: Transport(nil, Fixed(d, 0))}
+func TestTransportContextCanceled(t *testing.T) {
+	const extraLatency = 500 * time.Millisecond
+	const cancelLatency = 200 * time.Millisecond
+
+	s := httptest.NewServer(helloWorld)
+	defer s.Close()
+	client := &http.Client{Transport: Transport(nil, Fixed(extraLatency, 0))}
+
+	req, _ := http.NewRequest("GET", s.URL, nil)
+	ctx, cancel := context.WithTimeout(req.Context(), cancelLatency)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	var err error
+	responseTime := clock(func() {
+		_, err = client.Do(req)
+	})
+	if err == nil {
+		t.Fatal("Expected err: context deadline exceeded, got nil")
+	}
+	if !strings.Contains(err.Error(), "context deadline exceeded") {
+		t.Fatalf("Expected err: context deadline exceeded, got %T error %q", err, err.Error())
+	}
+	testDurationWithTolerance(t, responseTime, cancelLatency)
+}
+
+// RoundTrip must never return a non-nil response together with a non-nil
+// error (see http.RoundTripper). A Context cancellation during the
+// after-delay, i.e. once a response has already been obtained, must not
+// violate that contract.
+func TestTransportContextCanceledDuringAfterReturnsResponseWithNilError(t *testing.T) {
+	const afterLatency = 500 * time.Millisecond
+	const cancelLatency = 200 * time.Millisecond
+
+	s := httptest.NewServer(helloWorld)
+	defer s.Close()
+
+	rt := Transport(nil, Fixed(0, afterLatency))
+
+	req, _ := http.NewRequest("GET", s.URL, nil)
+	ctx, cancel := context.WithTimeout(req.Context(), cancelLatency)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	res, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("Expected a nil error once a response was obtained, got %v", err)
+	}
+	if res == nil {
+		t.Fatal("Expected a non-nil response")
+	}
+	res.Body.Close()
+}
+
+// Helper: call the server through the given client, while measuring response time.
+func callClient(t *testing.T, client *http.Client, url string) ([]byte, time.Duration) {
+	var res *http.Response
+	var err error
+	responseTime := clock(func() {
+		res, err = client.Get(url)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageBytes, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return messageBytes, responseTime
+}